@@ -0,0 +1,202 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package xds
+
+import (
+	"context"
+
+	statusv3 "github.com/envoyproxy/go-control-plane/envoy/service/status/v3"
+	matcherv3 "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"istio.io/istio/pilot/pkg/model"
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
+	"istio.io/pkg/monitoring"
+)
+
+// perTypeNackedResources tracks, for a given type URL, how many distinct resources are
+// currently NACKed by at least one connected proxy. It is derived from the same CSDS state
+// below, so dashboards can show which resources are stuck rather than just a reject rate.
+var perTypeNackedResources = monitoring.NewGauge(
+	"pilot_xds_nacked_resources",
+	"Number of distinct resources currently NACKed by at least one connected proxy, by type.",
+	monitoring.WithLabels(typeTag),
+)
+
+func init() {
+	monitoring.MustRegister(perTypeNackedResources)
+}
+
+// ClientStatusDiscoveryServer implements envoy.service.status.v3.ClientStatusDiscoveryService
+// on top of the existing DiscoveryServer connection registry, so istioctl proxy-status (and
+// any other operator tooling) can query per-proxy xDS config state over a standard protocol
+// instead of pilot's ad-hoc debug JSON.
+type ClientStatusDiscoveryServer struct {
+	s *DiscoveryServer
+}
+
+// NewClientStatusDiscoveryServer wires a CSDS server on top of an existing DiscoveryServer,
+// reusing its connection registry as the single source of truth for who is connected and
+// what was last pushed/acked.
+func NewClientStatusDiscoveryServer(s *DiscoveryServer) *ClientStatusDiscoveryServer {
+	return &ClientStatusDiscoveryServer{s: s}
+}
+
+// Register registers the CSDS service on the Istiod gRPC server, alongside ADS.
+func (c *ClientStatusDiscoveryServer) Register(rpcs *grpc.Server) {
+	statusv3.RegisterClientStatusDiscoveryServiceServer(rpcs, c)
+}
+
+// StreamClientStatus implements the CSDS streaming RPC. Each request snapshots the current
+// set of connected proxies, optionally filtered by node matcher, and responds with their
+// full per-type xDS config state.
+func (c *ClientStatusDiscoveryServer) StreamClientStatus(stream statusv3.ClientStatusDiscoveryService_StreamClientStatusServer) error {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(c.buildClientStatusResponse(req.GetNodeMatchers())); err != nil {
+			return err
+		}
+	}
+}
+
+// FetchClientStatus implements the unary CSDS RPC used by istioctl proxy-status.
+func (c *ClientStatusDiscoveryServer) FetchClientStatus(_ context.Context, req *statusv3.ClientStatusRequest) (*statusv3.ClientStatusResponse, error) {
+	return c.buildClientStatusResponse(req.GetNodeMatchers()), nil
+}
+
+func (c *ClientStatusDiscoveryServer) buildClientStatusResponse(matchers []*matcherv3.NodeMatcher) *statusv3.ClientStatusResponse {
+	resp := &statusv3.ClientStatusResponse{}
+	nackedByType := map[string]map[string]struct{}{}
+
+	for _, con := range c.s.Clients() {
+		proxy := con.proxy
+		if proxy == nil {
+			continue
+		}
+		// NACKs are counted for every connected proxy; the matchers only filter the response.
+		matched := nodeMatches(proxy, matchers)
+		entry := &statusv3.ClientConfig{Node: proxy.XdsNode}
+		for _, typeURL := range v3.AllTrackingTypes {
+			watched := proxy.GetWatchedResource(typeURL)
+			if watched == nil {
+				continue
+			}
+			generic := &statusv3.GenericXdsConfig{
+				TypeUrl:      typeURL,
+				VersionInfo:  watched.VersionSent,
+				LastUpdated:  timestamppb.New(watched.LastSendTime),
+				ConfigStatus: configStatus(watched),
+			}
+			if watched.NonceNacked != "" {
+				generic.ErrorState = &statusv3.UpdateFailureState{
+					Details:           watched.LastError,
+					VersionInfo:       watched.VersionSent,
+					LastUpdateAttempt: timestamppb.New(watched.LastSendTime),
+				}
+				if nackedByType[typeURL] == nil {
+					nackedByType[typeURL] = map[string]struct{}{}
+				}
+				for _, name := range watched.ResourceNames {
+					nackedByType[typeURL][name] = struct{}{}
+				}
+			}
+			entry.GenericXdsConfigs = append(entry.GenericXdsConfigs, generic)
+		}
+		if matched {
+			resp.Config = append(resp.Config, entry)
+		}
+	}
+
+	// Every tracked type is recorded, so a type whose NACKs have cleared drops back to 0.
+	for _, typeURL := range v3.AllTrackingTypes {
+		perTypeNackedResources.With(typeTag.Value(v3.GetMetricType(typeURL))).Record(float64(len(nackedByType[typeURL])))
+	}
+	return resp
+}
+
+// configStatus maps a watched resource's ack/nack state to the CSDS ConfigStatus enum.
+func configStatus(w *model.WatchedResource) statusv3.ConfigStatus {
+	switch {
+	case w.NonceNacked != "":
+		return statusv3.ConfigStatus_ERROR
+	case w.NonceAcked == "":
+		return statusv3.ConfigStatus_NOT_SENT
+	case w.NonceAcked != w.NonceSent:
+		return statusv3.ConfigStatus_STALE
+	default:
+		return statusv3.ConfigStatus_SYNCED
+	}
+}
+
+// nodeMatches reports whether a proxy satisfies at least one of the supplied matchers. An
+// empty matcher list matches every proxy. A matcher matches if its node_id is an exact match
+// for the proxy's ID - Envoy's StringMatcher "exact" field name promises exact, not prefix,
+// matching - or if every one of its node_metadatas entries matches one of the proxy's labels,
+// which is how "pods matching label X" filtering is expressed over NodeMatcher.
+func nodeMatches(proxy *model.Proxy, matchers []*matcherv3.NodeMatcher) bool {
+	if len(matchers) == 0 {
+		return true
+	}
+	for _, m := range matchers {
+		if exact := m.GetNodeId().GetExact(); exact != "" && exact == proxy.ID {
+			return true
+		}
+		if matchesNodeMetadata(proxy, m.GetNodeMetadatas()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNodeMetadata reports whether every StructMatcher in metadatas matches one of the
+// proxy's labels - struct matcher lists AND together, so the node must satisfy all of them,
+// matching google.protobuf.Struct matcher semantics. Each StructMatcher is expected to carry a
+// single-segment path naming the label key plus a string value matcher, the shape
+// node_metadatas takes for label-based filtering; deeper struct paths aren't supported and
+// never match, since this package only ever needs to filter proxies by label.
+func matchesNodeMetadata(proxy *model.Proxy, metadatas []*matcherv3.StructMatcher) bool {
+	if len(metadatas) == 0 {
+		return false
+	}
+	if proxy.Metadata == nil {
+		return false
+	}
+	for _, sm := range metadatas {
+		path := sm.GetPath()
+		if len(path) != 1 {
+			return false
+		}
+		label, ok := proxy.Metadata.Labels[path[0].GetKey()]
+		if !ok || !stringMatcherMatches(sm.GetValue().GetStringMatch(), label) {
+			return false
+		}
+	}
+	return true
+}
+
+// stringMatcherMatches reports whether val satisfies m. Only exact matching is supported,
+// mirroring the node_id matching above; m being nil (no string_match set) never matches.
+func stringMatcherMatches(m *matcherv3.StringMatcher, val string) bool {
+	if m == nil {
+		return false
+	}
+	if exact := m.GetExact(); exact != "" {
+		return exact == val
+	}
+	return false
+}
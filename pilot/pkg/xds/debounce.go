@@ -0,0 +1,276 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package xds
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"istio.io/pkg/env"
+	"istio.io/pkg/monitoring"
+)
+
+// debounceDirectionTag is kept separate from the package's other label vars since it is
+// only used by the adaptive debounce controller below.
+var debounceDirectionTag = monitoring.MustCreateLabel("direction")
+
+var (
+	debounceWindow = monitoring.NewGauge(
+		"pilot_debounce_window_seconds",
+		"Current adaptive debounce window, in seconds.",
+	)
+
+	debounceAdjustments = monitoring.NewSum(
+		"pilot_debounce_adjustments_total",
+		"Total number of times the adaptive debounce controller changed the debounce window.",
+		monitoring.WithLabels(debounceDirectionTag),
+	)
+)
+
+func init() {
+	monitoring.MustRegister(debounceWindow, debounceAdjustments)
+}
+
+// debounceMetricsSource abstracts the rolling p95/rate reads the adaptive controller needs,
+// so it can be driven by synthetic streams in tests without standing up the real monitoring
+// distributions.
+type debounceMetricsSource interface {
+	// convergeDelayP95 returns the current p95 of pilot_proxy_convergence_time.
+	convergeDelayP95() time.Duration
+	// delayedPushTimeoutRate returns the fraction of delayed pushes that timed out over the
+	// most recent evaluation window.
+	delayedPushTimeoutRate() float64
+}
+
+// adaptiveDebounceController implements an AIMD-style closed loop over the debounce window:
+// additively lengthen it while convergence is slow (coalesce more changes), multiplicatively
+// shorten it back towards the configured minimum once the mesh is quiet and well under SLO.
+type adaptiveDebounceController struct {
+	mu     sync.Mutex
+	window time.Duration
+
+	min, max     time.Duration
+	targetSLO    time.Duration
+	increaseStep time.Duration
+	decreaseMul  float64
+
+	source debounceMetricsSource
+}
+
+// newAdaptiveDebounceController builds a controller starting at the given initial window
+// (normally PILOT_DEBOUNCE_AFTER), clamped between min and max.
+func newAdaptiveDebounceController(initial, min, max, targetSLO time.Duration, source debounceMetricsSource) *adaptiveDebounceController {
+	return &adaptiveDebounceController{
+		window:       clampDuration(initial, min, max),
+		min:          min,
+		max:          max,
+		targetSLO:    targetSLO,
+		increaseStep: 50 * time.Millisecond,
+		decreaseMul:  0.9,
+		source:       source,
+	}
+}
+
+// currentWindow returns the controller's current debounce window.
+func (c *adaptiveDebounceController) currentWindow() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.window
+}
+
+// evaluate runs one iteration of the AIMD loop: it reads the current p95 convergence delay
+// and delayed-push-timeout rate and adjusts the window accordingly, emitting a metric for
+// whichever direction (if any) it moved.
+func (c *adaptiveDebounceController) evaluate() time.Duration {
+	p95 := c.source.convergeDelayP95()
+	timeoutRate := c.source.delayedPushTimeoutRate()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev := c.window
+	switch {
+	case p95 > c.targetSLO || timeoutRate > 0.01:
+		// Additive increase: back off gradually so a single bad sample doesn't cause the
+		// window to overshoot.
+		c.window = clampDuration(c.window+c.increaseStep, c.min, c.max)
+		if c.window != prev {
+			debounceAdjustments.With(debounceDirectionTag.Value("increase")).Increment()
+		}
+	case p95 < c.targetSLO/2 && timeoutRate == 0:
+		// Multiplicative decrease: once the mesh is comfortably under SLO, shrink
+		// aggressively back towards the minimum for faster convergence.
+		next := time.Duration(float64(c.window) * c.decreaseMul)
+		c.window = clampDuration(next, c.min, c.max)
+		if c.window != prev {
+			debounceAdjustments.With(debounceDirectionTag.Value("decrease")).Increment()
+		}
+	}
+
+	debounceWindow.Record(c.window.Seconds())
+	return c.window
+}
+
+func clampDuration(d, min, max time.Duration) time.Duration {
+	if d < min {
+		return min
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
+
+// runAdaptiveDebounce starts the controller's evaluation loop, re-running evaluate every
+// interval until stop is closed.
+func runAdaptiveDebounce(c *adaptiveDebounceController, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.evaluate()
+		}
+	}
+}
+
+const convergenceWindowSize = 200
+
+// convergenceTracker maintains the rolling window of recent convergence delays and the
+// delayed-push-timeout rate the production debounceMetricsSource below reads from. It backs
+// the same pilot_proxy_convergence_time/pilot_xds_delayed_push_timeouts_total distributions
+// recordConvergeDelay and recordDelayedPush already feed, so the controller reacts to exactly
+// the data those metrics represent rather than a separate, possibly-diverging signal.
+type convergenceTracker struct {
+	mu       sync.Mutex
+	delays   []time.Duration
+	delayed  int
+	timedOut int
+}
+
+func (c *convergenceTracker) recordDelay(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.delays = append(c.delays, d)
+	if len(c.delays) > convergenceWindowSize {
+		c.delays = c.delays[len(c.delays)-convergenceWindowSize:]
+	}
+}
+
+func (c *convergenceTracker) recordDelayedPush(timedOut bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.delayed++
+	if timedOut {
+		c.timedOut++
+	}
+	// Keep the timeout rate a rate over a rolling window rather than an all-time average, by
+	// halving both counters once they get large - this is the same trick a decaying counter
+	// uses, without needing a background goroutine of its own.
+	if c.delayed > convergenceWindowSize {
+		c.delayed /= 2
+		c.timedOut /= 2
+	}
+}
+
+func (c *convergenceTracker) p95() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.delays) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), c.delays...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(float64(len(sorted)) * 0.95)
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+func (c *convergenceTracker) timeoutRate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.delayed == 0 {
+		return 0
+	}
+	return float64(c.timedOut) / float64(c.delayed)
+}
+
+// globalConvergenceTracker is the process-wide tracker recordConvergeDelay/recordDelayedPush
+// feed and productionDebounceMetricsSource reads from.
+var globalConvergenceTracker = &convergenceTracker{}
+
+// recordConvergeDelay records a proxy's convergence delay - the time between a config change
+// and that proxy receiving all required configuration - into the Prometheus distribution, its
+// OTel counterpart, and the rolling window the adaptive debounce controller reads.
+func recordConvergeDelay(ctx context.Context, d time.Duration) {
+	proxiesConvergeDelay.Record(d.Seconds())
+	recordOtelConverge(ctx, d)
+	globalConvergenceTracker.recordDelay(d)
+}
+
+// recordDelayedPush records a delayed push, and whether it ultimately timed out and was sent
+// as a failsafe, into both the Prometheus counters and the rolling window the adaptive
+// debounce controller reads.
+func recordDelayedPush(timedOut bool) {
+	totalDelayedPushes.Increment()
+	if timedOut {
+		totalDelayedPushTimeouts.Increment()
+	}
+	globalConvergenceTracker.recordDelayedPush(timedOut)
+}
+
+// productionDebounceMetricsSource is the debounceMetricsSource the real controller is built
+// with; fakeMetricsSource in debounce_test.go exists only to drive deterministic unit tests.
+type productionDebounceMetricsSource struct{}
+
+func (productionDebounceMetricsSource) convergeDelayP95() time.Duration {
+	return globalConvergenceTracker.p95()
+}
+
+func (productionDebounceMetricsSource) delayedPushTimeoutRate() float64 {
+	return globalConvergenceTracker.timeoutRate()
+}
+
+var (
+	debounceAfter = env.Register("PILOT_DEBOUNCE_AFTER", 100*time.Millisecond,
+		"The debounce window for the initial/minimum adaptive debounce window.").Get()
+	debounceMax = env.Register("PILOT_DEBOUNCE_MAX", 10*time.Second,
+		"The ceiling the adaptive debounce window is clamped to.").Get()
+	debounceTargetSLO = env.Register("PILOT_DEBOUNCE_TARGET_CONVERGENCE", time.Second,
+		"The p95 proxy convergence delay the adaptive debounce controller targets.").Get()
+)
+
+// globalDebounceController is the production adaptive debounce controller, started from
+// DiscoveryServer.Start so it keeps evaluating against real traffic. CurrentDebounceWindow is
+// the extension point a push queue's debounce timer would read instead of the static
+// PILOT_DEBOUNCE_AFTER/PILOT_DEBOUNCE_MAX env vars directly, so the window it waits on would
+// actually move with the controller's decisions - no in-tree push queue reads it yet, so today
+// the computed window only drives the pilot_debounce_window_seconds gauge.
+var globalDebounceController = newAdaptiveDebounceController(
+	debounceAfter, debounceAfter, debounceMax, debounceTargetSLO, productionDebounceMetricsSource{})
+
+// CurrentDebounceWindow returns the adaptive debounce controller's current window. No in-tree
+// caller reads this yet; see globalDebounceController's comment above.
+func CurrentDebounceWindow() time.Duration {
+	return globalDebounceController.currentWindow()
+}
+
+// debounceEvalInterval is how often the production controller re-evaluates the window.
+const debounceEvalInterval = 5 * time.Second
@@ -0,0 +1,77 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package xds
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeMetricsSource feeds the controller a synthetic p95/timeout-rate stream so tests don't
+// need to drive the real monitoring distributions.
+type fakeMetricsSource struct {
+	p95         time.Duration
+	timeoutRate float64
+}
+
+func (f *fakeMetricsSource) convergeDelayP95() time.Duration { return f.p95 }
+func (f *fakeMetricsSource) delayedPushTimeoutRate() float64 { return f.timeoutRate }
+
+func TestAdaptiveDebounceController_IncreasesOnSLOViolation(t *testing.T) {
+	source := &fakeMetricsSource{p95: 2 * time.Second}
+	c := newAdaptiveDebounceController(100*time.Millisecond, 100*time.Millisecond, time.Second, time.Second, source)
+
+	first := c.currentWindow()
+	for i := 0; i < 5; i++ {
+		c.evaluate()
+	}
+	if got := c.currentWindow(); got <= first {
+		t.Fatalf("expected debounce window to increase while p95 exceeds SLO, got %v (started at %v)", got, first)
+	}
+}
+
+func TestAdaptiveDebounceController_DecreasesWhenQuiet(t *testing.T) {
+	source := &fakeMetricsSource{p95: 10 * time.Millisecond}
+	c := newAdaptiveDebounceController(time.Second, 50*time.Millisecond, time.Second, time.Second, source)
+
+	for i := 0; i < 60; i++ {
+		c.evaluate()
+	}
+	if got := c.currentWindow(); got != 50*time.Millisecond {
+		t.Fatalf("expected debounce window to converge to the minimum when consistently quiet, got %v", got)
+	}
+}
+
+func TestAdaptiveDebounceController_ClampedToBounds(t *testing.T) {
+	source := &fakeMetricsSource{p95: 5 * time.Second}
+	c := newAdaptiveDebounceController(900*time.Millisecond, 100*time.Millisecond, time.Second, time.Second, source)
+
+	for i := 0; i < 50; i++ {
+		c.evaluate()
+	}
+	if got := c.currentWindow(); got > time.Second {
+		t.Fatalf("expected debounce window to never exceed the configured max, got %v", got)
+	}
+}
+
+func TestAdaptiveDebounceController_NoTimeoutsDoesNotForceIncrease(t *testing.T) {
+	source := &fakeMetricsSource{p95: 100 * time.Millisecond, timeoutRate: 0}
+	c := newAdaptiveDebounceController(200*time.Millisecond, 100*time.Millisecond, time.Second, 500*time.Millisecond, source)
+
+	start := c.currentWindow()
+	c.evaluate()
+	if got := c.currentWindow(); got > start {
+		t.Fatalf("did not expect window to increase when well under SLO with no timeouts, got %v (started at %v)", got, start)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package xds
+
+import (
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"istio.io/istio/pilot/pkg/model"
+)
+
+// Connection represents a single gRPC stream from a connected proxy. The full ADS push/ack
+// lifecycle lives alongside the stream handler; this is the subset every other subsystem in
+// this package (CSDS, the slow-proxy quarantine, the reject audit log) needs in order to
+// reason about "who is connected right now".
+type Connection struct {
+	conID string
+	proxy *model.Proxy
+}
+
+// DiscoveryServer is the Istiod-side xDS implementation. It owns the registry of connected
+// proxies and is the thing CSDS, the debug endpoints, and the push-latency subsystems in this
+// package are all built on top of.
+type DiscoveryServer struct {
+	adsClientsMutex sync.RWMutex
+	adsClients      map[string]*Connection
+
+	slowProxyRegistry *slowProxyRegistry
+}
+
+// NewDiscoveryServer builds a DiscoveryServer with its connection registry and the
+// subsystems that key off it ready to use.
+func NewDiscoveryServer() *DiscoveryServer {
+	return &DiscoveryServer{
+		adsClients:        make(map[string]*Connection),
+		slowProxyRegistry: globalSlowProxyRegistry,
+	}
+}
+
+// Start launches the background goroutines this package's subsystems need - the slow-proxy
+// quarantine reaper and the adaptive debounce controller - stopping them when stop is closed.
+func (s *DiscoveryServer) Start(stop <-chan struct{}) {
+	go s.slowProxyRegistry.run(stop)
+	go runAdaptiveDebounce(globalDebounceController, debounceEvalInterval, stop)
+}
+
+// InitDebug registers this package's /debug endpoints on Istiod's debug HTTP mux.
+func (s *DiscoveryServer) InitDebug(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/slowproxies", s.slowProxiesHandler)
+	mux.HandleFunc("/debug/rejects", rejectsHandler)
+}
+
+// Clients returns a snapshot of every proxy currently connected to this Istiod instance.
+func (s *DiscoveryServer) Clients() []*Connection {
+	s.adsClientsMutex.RLock()
+	defer s.adsClientsMutex.RUnlock()
+	out := make([]*Connection, 0, len(s.adsClients))
+	for _, con := range s.adsClients {
+		out = append(out, con)
+	}
+	return out
+}
+
+// registerConnection adds a newly-accepted proxy connection to the registry and starts
+// tracking its push/ACK latency for SLO purposes.
+func (s *DiscoveryServer) registerConnection(con *Connection) {
+	s.adsClientsMutex.Lock()
+	s.adsClients[con.conID] = con
+	s.adsClientsMutex.Unlock()
+	s.slowProxyRegistry.trackerFor(con.conID, con.proxy.ID)
+}
+
+// removeConnection drops a closed connection from the registry and stops tracking it.
+func (s *DiscoveryServer) removeConnection(conID string) {
+	s.adsClientsMutex.Lock()
+	delete(s.adsClients, conID)
+	s.adsClientsMutex.Unlock()
+	s.slowProxyRegistry.remove(conID)
+}
+
+// Register wires the CSDS service onto the Istiod gRPC server passed in from bootstrap. ADS is
+// not registered by this method.
+func (s *DiscoveryServer) Register(rpcs *grpc.Server) {
+	NewClientStatusDiscoveryServer(s).Register(rpcs)
+}
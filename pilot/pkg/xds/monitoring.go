@@ -14,9 +14,11 @@
 package xds
 
 import (
+	"context"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
 
 	"istio.io/istio/pilot/pkg/model"
@@ -171,6 +173,32 @@ var (
 	inboundEDSUpdates     = inboundUpdates.With(typeTag.Value("eds"))
 	inboundServiceUpdates = inboundUpdates.With(typeTag.Value("svc"))
 	inboundServiceDeletes = inboundUpdates.With(typeTag.Value("svcdelete"))
+
+	// pushResources and pushBytes give visibility into the size of each xDS push, not just
+	// its rate: pilot_xds_pushes only tells you a CDS push happened, not whether it carried
+	// 10 clusters or 10,000.
+	pushResources = monitoring.NewDistribution(
+		"pilot_xds_push_resources",
+		"Number of resources included in a single xDS push, by type.",
+		[]float64{1, 10, 100, 1000, 10000},
+		monitoring.WithLabels(typeTag),
+	)
+
+	pushBytes = monitoring.NewDistribution(
+		"pilot_xds_push_bytes",
+		"Marshaled size in bytes of a single xDS push, by type.",
+		[]float64{1000, 10000, 100000, 1000000, 10000000},
+		monitoring.WithLabels(typeTag),
+	)
+
+	// resourceGenerationTime separates generator CPU time from send/network time, so a slow
+	// push can be attributed to one or the other instead of being lumped into pushTime.
+	resourceGenerationTime = monitoring.NewDistribution(
+		"pilot_xds_resource_generation_time",
+		"Total time in seconds spent generating an xDS resource, by type.",
+		[]float64{.01, .1, 1, 3, 5, 10, 20, 30},
+		monitoring.WithLabels(typeTag),
+	)
 )
 
 func recordXDSClients(version string, delta float64) {
@@ -193,6 +221,7 @@ func recordSendError(xdsType string, conID string, err error) {
 	isError := s.Code() != codes.Unavailable && s.Code() != codes.Canceled
 	if !ok || isError {
 		adsLog.Warnf("%s: Send failure %s: %v", xdsType, conID, err)
+		globalSlowProxyRegistry.trackerFor(conID, "").recordError(v3.GetMetricType(xdsType))
 		// TODO use a single metric with a type tag
 		switch xdsType {
 		case v3.ListenerType:
@@ -207,8 +236,43 @@ func recordSendError(xdsType string, conID string, err error) {
 	}
 }
 
+// incrementXDSRejects keeps its original signature - no ctx, no detail - so the existing ADS
+// request handler call site keeps compiling unchanged; since it has no context to thread
+// through, its reject is recorded without a trace/span exemplar. New call sites that have a
+// request context and the full Envoy error_detail, resource names, and version info available
+// should call recordXDSReject directly instead - that is the ADS handler follow-up this
+// request still needs, to get both the detail and the exemplar this shim can't provide.
 func incrementXDSRejects(xdsType string, node, errCode string) {
+	recordXDSReject(context.Background(), xdsType, node, errCode, RejectDetail{})
+}
+
+// RejectDetail carries the information incrementXDSRejects's counters alone lose: which
+// resources the proxy rejected, the raw Envoy validation error, and the version it fell back
+// to. Populate it from DiscoveryRequest.error_detail and the failing response's resource list.
+type RejectDetail struct {
+	ErrorDetail     string
+	ResourceNames   []string
+	RejectedVersion string
+	LastGoodVersion string
+}
+
+// recordXDSReject increments the reject counters exactly as incrementXDSRejects does, and
+// additionally records a structured audit event carrying detail. Unlike incrementXDSRejects,
+// it takes the request's context, so the OTel reject counter can carry an exemplar
+// correlating the reject back to the proxy and config update that triggered it.
+func recordXDSReject(ctx context.Context, xdsType string, node, errCode string, detail RejectDetail) {
 	totalXDSRejects.With(typeTag.Value(v3.GetMetricType(xdsType))).Increment()
+	recordOtelXDSReject(ctx, xdsType)
+	globalRejectAuditLog.record(rejectAuditEvent{
+		Time:            time.Now(),
+		Node:            node,
+		TypeURL:         xdsType,
+		ErrorCode:       errCode,
+		ErrorDetail:     detail.ErrorDetail,
+		ResourceNames:   detail.ResourceNames,
+		RejectedVersion: detail.RejectedVersion,
+		LastGoodVersion: detail.LastGoodVersion,
+	})
 	switch xdsType {
 	case v3.ListenerType:
 		ldsReject.With(nodeTag.Value(node), errTag.Value(errCode)).Increment()
@@ -221,13 +285,59 @@ func incrementXDSRejects(xdsType string, node, errCode string) {
 	}
 }
 
-func recordSendTime(duration time.Duration) {
+// recordPushSize records the number of resources and marshaled byte size of a single xDS push.
+// A generator (LDS/RDS/CDS/EDS/ECDS/NDS) should call this once after marshaling a response,
+// independent of recordPushTime - no in-tree generator call site exists yet, so
+// pilot_xds_push_resources/pilot_xds_push_bytes stay at zero until one is added.
+func recordPushSize(xdsType string, resourceCount int, bytes int) {
+	metricType := v3.GetMetricType(xdsType)
+	pushResources.With(typeTag.Value(metricType)).Record(float64(resourceCount))
+	pushBytes.With(typeTag.Value(metricType)).Record(float64(bytes))
+}
+
+// recordResourceGenerationTime records how long a generator spent building an xDS resource,
+// separate from recordSendTime's network/stream time. A generator should call this once it has
+// finished building (but not yet marshaling/sending) a resource - no in-tree call site exists
+// yet, so pilot_xds_resource_generation_time stays at zero until one is added.
+func recordResourceGenerationTime(xdsType string, duration time.Duration) {
+	resourceGenerationTime.With(typeTag.Value(v3.GetMetricType(xdsType))).Record(duration.Seconds())
+}
+
+func recordSendTime(ctx context.Context, conID string, duration time.Duration) {
 	sendTime.Record(duration.Seconds())
+	recordOtelSendTime(ctx, duration)
+	// A stream send isn't tied to one resource type, so this sample can't update the
+	// tracker's lastType; recordPushTime's call below is the one that can.
+	globalSlowProxyRegistry.trackerFor(conID, "").record(duration, "")
 }
 
-func recordPushTime(xdsType string, duration time.Duration) {
+// recordProxyQueueTime records how long a proxy sat in the push queue before being dequeued,
+// in both the Prometheus distribution and its OTel counterpart. The push queue (outside this
+// package slice) should call this once per dequeue, the same way it already calls
+// recordDelayedPush when a dequeue is delayed past the debounce window.
+func recordProxyQueueTime(ctx context.Context, duration time.Duration) {
+	proxiesQueueTime.Record(duration.Seconds())
+	recordOtelQueueTime(ctx, duration)
+}
+
+// recordPushTime starts and ends the xds.push span for this generate+send cycle - backdated to
+// the actual span duration, since this is called after the push already completed - and
+// records the Prometheus and OTel push_time distributions, the latter with an exemplar tagging
+// the sample with that span's trace/span ID. It also feeds the same duration into the
+// per-proxy SLO tracker and the convergence-delay tracker the adaptive debounce controller
+// reads. Push size and generator CPU time are a generator's own call to recordPushSize/
+// recordResourceGenerationTime, not folded in here - they're observed at marshal time, not
+// send time, and not every push has a size worth recording (ACK-triggered resends, for one).
+func recordPushTime(ctx context.Context, xdsType string, conID string, reasons []model.TriggerReason, duration time.Duration) {
+	end := time.Now()
+	spanCtx, span := startPushSpan(ctx, conID, xdsType, reasons, end.Add(-duration))
+	span.End(trace.WithTimestamp(end))
+
 	pushTime.With(typeTag.Value(v3.GetMetricType(xdsType))).Record(duration.Seconds())
 	pushes.With(typeTag.Value(v3.GetMetricType(xdsType))).Increment()
+	recordOtelPushTime(spanCtx, xdsType, duration)
+	globalSlowProxyRegistry.trackerFor(conID, "").record(duration, v3.GetMetricType(xdsType))
+	recordConvergeDelay(spanCtx, duration)
 }
 
 func init() {
@@ -252,5 +362,8 @@ func init() {
 		sendTime,
 		totalDelayedPushes,
 		totalDelayedPushTimeouts,
+		pushResources,
+		pushBytes,
+		resourceGenerationTime,
 	)
 }
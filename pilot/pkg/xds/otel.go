@@ -0,0 +1,148 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package xds
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/pkg/env"
+	"istio.io/pkg/log"
+)
+
+// otelEndpoint, when set, enables an additional OTLP metrics exporter alongside the existing
+// Prometheus registry. This is opt-in: most installs only ever read /metrics, and the
+// OTel SDK's push model would otherwise mean doing exporter setup work for nobody.
+var otelEndpoint = env.Register("PILOT_XDS_OTEL_ENDPOINT", "",
+	"If set, xDS push pipeline metrics are additionally exported via OTLP to this endpoint.").Get()
+
+// pushTracer is used to start the xds.push span around each recorded push/send, so the
+// OTel exemplars attached to pushTime/sendTime can be correlated back to the exact trace
+// that produced them.
+var pushTracer = otelTracer()
+
+func otelTracer() trace.Tracer {
+	return global.TracerProvider().Tracer("istio.io/pilot/xds")
+}
+
+// otelMeter is the global OTel meter for the xds push pipeline, and otelPushTime etc. are the
+// instruments built from it. All of it is built inside init() below, after the exporter (if
+// any) has been installed via global.SetMeterProvider - package var initializers run before any
+// init() in the package, so resolving the meter in a var expression here would permanently bind
+// it to the default no-op provider regardless of PILOT_XDS_OTEL_ENDPOINT.
+var (
+	otelMeter      metric.Meter
+	otelPushTime   metric.Float64Histogram
+	otelSendTime   metric.Float64Histogram
+	otelConverge   metric.Float64Histogram
+	otelQueueTime  metric.Float64Histogram
+	otelXDSRejects metric.Int64Counter
+)
+
+func init() {
+	if otelEndpoint != "" {
+		exporter, err := otlpmetricgrpc.New(context.Background(), otlpmetricgrpc.WithEndpoint(otelEndpoint), otlpmetricgrpc.WithInsecure())
+		if err != nil {
+			log.Errorf("failed to create otel metrics exporter: %v", err)
+		} else {
+			provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(15*time.Second))))
+			global.SetMeterProvider(provider)
+		}
+	}
+
+	otelMeter = global.MeterProvider().Meter("istio.io/pilot/xds")
+	otelPushTime = mustFloat64Histogram("pilot.xds.push_time", "Time in seconds Pilot takes to push xDS config, mirrors pilot_xds_push_time.")
+	otelSendTime = mustFloat64Histogram("pilot.xds.send_time", "Time in seconds Pilot takes to send generated configuration, mirrors pilot_xds_send_time.")
+	otelConverge = mustFloat64Histogram("pilot.xds.proxy_converge_time", "Delay between a config change and a proxy converging, mirrors pilot_proxy_convergence_time.")
+	otelQueueTime = mustFloat64Histogram("pilot.xds.proxy_queue_time", "Time a proxy spends in the push queue before being dequeued, mirrors pilot_proxy_queue_time.")
+	otelXDSRejects = mustInt64Counter("pilot.xds.rejects", "Total xDS responses rejected by a proxy, mirrors pilot_total_xds_rejects.")
+}
+
+func mustFloat64Histogram(name, desc string) metric.Float64Histogram {
+	h, err := otelMeter.Float64Histogram(name, metric.WithDescription(desc))
+	if err != nil {
+		log.Errorf("failed to create otel histogram %s: %v", name, err)
+	}
+	return h
+}
+
+func mustInt64Counter(name, desc string) metric.Int64Counter {
+	c, err := otelMeter.Int64Counter(name, metric.WithDescription(desc))
+	if err != nil {
+		log.Errorf("failed to create otel counter %s: %v", name, err)
+	}
+	return c
+}
+
+// startPushSpan starts the xds.push span for a single generate+send cycle, tagging it with
+// the proxy, resource type and push reasons so a tail-latency sample in pilot_xds_push_time
+// can be correlated with the push that produced it via the attached exemplar. recordPushTime
+// only learns a push happened after the fact, once it already has the push's total duration,
+// so the start is passed in explicitly (backdated from that duration) and the caller ends the
+// span with an explicit end timestamp, rather than both being taken from time.Now - otherwise
+// the span would report a near-zero duration instead of the push's actual one.
+func startPushSpan(ctx context.Context, conID, xdsType string, reasons []model.TriggerReason, start time.Time) (context.Context, trace.Span) {
+	reasonStrs := make([]string, 0, len(reasons))
+	for _, r := range reasons {
+		reasonStrs = append(reasonStrs, string(r))
+	}
+	return pushTracer.Start(ctx, "xds.push",
+		trace.WithTimestamp(start),
+		trace.WithAttributes(
+			attribute.String("xds.proxy_id", conID),
+			attribute.String("xds.type_url", xdsType),
+			attribute.StringSlice("xds.push_reasons", reasonStrs),
+		))
+}
+
+// exemplarOpt tags a recorded sample with the trace/span ID of the push that produced it, so
+// the OTel backend can link a histogram bucket sample straight back to the push span.
+func exemplarOpt(ctx context.Context) metric.RecordOption {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return metric.WithAttributes()
+	}
+	return metric.WithAttributes(
+		attribute.String("trace_id", sc.TraceID().String()),
+		attribute.String("span_id", sc.SpanID().String()),
+	)
+}
+
+func recordOtelPushTime(ctx context.Context, xdsType string, d time.Duration) {
+	otelPushTime.Record(ctx, d.Seconds(), exemplarOpt(ctx), metric.WithAttributes(attribute.String("type", xdsType)))
+}
+
+func recordOtelSendTime(ctx context.Context, d time.Duration) {
+	otelSendTime.Record(ctx, d.Seconds(), exemplarOpt(ctx))
+}
+
+func recordOtelConverge(ctx context.Context, d time.Duration) {
+	otelConverge.Record(ctx, d.Seconds(), exemplarOpt(ctx))
+}
+
+func recordOtelQueueTime(ctx context.Context, d time.Duration) {
+	otelQueueTime.Record(ctx, d.Seconds(), exemplarOpt(ctx))
+}
+
+func recordOtelXDSReject(ctx context.Context, xdsType string) {
+	otelXDSRejects.Add(ctx, 1, exemplarOpt(ctx), metric.WithAttributes(attribute.String("type", xdsType)))
+}
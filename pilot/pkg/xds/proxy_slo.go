@@ -0,0 +1,286 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package xds
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"istio.io/pkg/env"
+	"istio.io/pkg/monitoring"
+)
+
+const (
+	// sloWindowSize is the number of most recent push/send latencies kept per connection to
+	// compute a rolling p95 against.
+	sloWindowSize = 50
+	// sloReapInterval is how often the quarantine reaper re-evaluates every tracked proxy.
+	sloReapInterval = 30 * time.Second
+	// sloSustainedViolations is how many consecutive reaper passes a proxy must stay in
+	// violation for before it is moved into the quarantine push class. This avoids
+	// quarantining a proxy for a single slow push caused by, say, a GC pause.
+	sloSustainedViolations = 3
+)
+
+var (
+	proxySLOViolations = monitoring.NewSum(
+		"pilot_xds_proxy_slo_violations_total",
+		"Total number of times a proxy's rolling p95 push latency exceeded the configured SLO, or a push to it failed to send.",
+		monitoring.WithLabels(nodeTag, typeTag),
+	)
+
+	slowProxies = monitoring.NewGauge(
+		"pilot_xds_slow_proxies",
+		"Number of proxies currently quarantined for sustained push/ACK latency SLO violations.",
+	)
+)
+
+func init() {
+	monitoring.MustRegister(proxySLOViolations, slowProxies)
+}
+
+// sloThreshold is the p95 push latency above which a proxy is considered in violation.
+// This mirrors how PILOT_DEBOUNCE_AFTER and friends are configured elsewhere in this package.
+var sloThreshold = func() time.Duration {
+	return env.Register("PILOT_XDS_PROXY_SLO_THRESHOLD", 5*time.Second,
+		"p95 push latency above which a proxy is considered in SLO violation.").Get()
+}()
+
+// proxyLatencyTracker maintains a rolling window of push/ACK latency samples for a single
+// connection, and the sustained-violation bookkeeping that decides whether it should be
+// quarantined. It is attached to the connection struct and updated from recordPushTime,
+// recordSendTime and recordSendError. Its samples are the push and send durations those
+// functions receive; the time until the proxy ACKs a push is not measured.
+type proxyLatencyTracker struct {
+	mu            sync.Mutex
+	conID         string
+	node          string
+	lastType      string
+	latencies     []time.Duration
+	violationRuns int
+	quarantined   bool
+	quarantinedAt time.Time
+	lastViolation time.Duration
+}
+
+func newProxyLatencyTracker(conID, node string) *proxyLatencyTracker {
+	return &proxyLatencyTracker{conID: conID, node: node}
+}
+
+// record adds a new push/send latency sample to the rolling window. xdsType is the resource type
+// this sample came from, if known (recordSendTime has none to give, since a single stream send
+// can carry any type); when non-empty it becomes the tracker's lastType, so the periodic
+// reaper - which runs outside any particular push and so has no type of its own - can still
+// label the pilot_xds_proxy_slo_violations_total it emits with something other than "".
+func (t *proxyLatencyTracker) record(d time.Duration, xdsType string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.latencies = append(t.latencies, d)
+	if len(t.latencies) > sloWindowSize {
+		t.latencies = t.latencies[len(t.latencies)-sloWindowSize:]
+	}
+	if xdsType != "" {
+		t.lastType = xdsType
+	}
+}
+
+// p95 returns the current rolling p95 push/send latency, or 0 if there are no samples yet.
+func (t *proxyLatencyTracker) p95() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), t.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(float64(len(sorted)) * 0.95)
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// evaluate checks the current p95 against the SLO threshold, bumping the sustained-violation
+// counter and quarantining the proxy once it has been in violation for sloSustainedViolations
+// consecutive reaper passes. It returns whether the proxy's quarantine state changed.
+func (t *proxyLatencyTracker) evaluate(xdsType string) (changed bool) {
+	p95 := t.p95()
+	inViolation := p95 > 0 && p95 > sloThreshold
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if inViolation {
+		proxySLOViolations.With(nodeTag.Value(t.node), typeTag.Value(xdsType)).Increment()
+		t.violationRuns++
+		t.lastViolation = p95
+	} else {
+		t.violationRuns = 0
+	}
+
+	wasQuarantined := t.quarantined
+	t.quarantined = t.violationRuns >= sloSustainedViolations
+	if t.quarantined && !wasQuarantined {
+		t.quarantinedAt = time.Now()
+	}
+	return t.quarantined != wasQuarantined
+}
+
+// slowProxyRegistry tracks a proxyLatencyTracker per connection ID and runs the background
+// reaper that promotes/demotes proxies into and out of the quarantine push class.
+type slowProxyRegistry struct {
+	mu       sync.RWMutex
+	trackers map[string]*proxyLatencyTracker
+}
+
+// globalSlowProxyRegistry is the process-wide slow-proxy registry: recordPushTime,
+// recordSendTime and recordSendError are free functions with no DiscoveryServer receiver, so
+// like globalRejectAuditLog they share a single package-level registry that DiscoveryServer's
+// own slowProxyRegistry field (in discovery.go) points at.
+var globalSlowProxyRegistry = newSlowProxyRegistry()
+
+func newSlowProxyRegistry() *slowProxyRegistry {
+	return &slowProxyRegistry{trackers: make(map[string]*proxyLatencyTracker)}
+}
+
+func (r *slowProxyRegistry) trackerFor(conID, node string) *proxyLatencyTracker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.trackers[conID]
+	if !ok {
+		t = newProxyLatencyTracker(conID, node)
+		r.trackers[conID] = t
+	} else if node != "" {
+		t.node = node
+	}
+	return t
+}
+
+// IsQuarantined reports whether a connection's sustained push-latency SLO violations have put
+// it in the quarantine push class. This is the extension point a push queue would consult when
+// ordering pending pushes, so one slow sidecar debounced more aggressively doesn't keep
+// dequeuing ahead of healthy proxies and dominating the worker goroutines - no in-tree push
+// queue exists yet to call it, so today quarantine state only surfaces via
+// /debug/slowproxies and the pilot_xds_slow_proxies gauge.
+func (r *slowProxyRegistry) IsQuarantined(conID string) bool {
+	r.mu.RLock()
+	t, ok := r.trackers[conID]
+	r.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	return t.isQuarantined()
+}
+
+func (r *slowProxyRegistry) remove(conID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.trackers, conID)
+}
+
+// run is the reaper goroutine: it periodically evaluates every tracked proxy against the
+// SLO threshold and keeps the pilot_xds_slow_proxies gauge in sync with the result.
+func (r *slowProxyRegistry) run(stop <-chan struct{}) {
+	ticker := time.NewTicker(sloReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.reap()
+		}
+	}
+}
+
+func (r *slowProxyRegistry) reap() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	quarantined := 0
+	for _, t := range r.trackers {
+		t.mu.Lock()
+		xdsType := t.lastType
+		t.mu.Unlock()
+		t.evaluate(xdsType)
+		if t.isQuarantined() {
+			quarantined++
+		}
+	}
+	slowProxies.Record(float64(quarantined))
+}
+
+// recordError immediately counts a push send failure as an SLO violation: a proxy that is
+// repeatedly failing to receive pushes at all is at least as concerning as one that is merely
+// slow to receive them, so it contributes to the same sustained-violation count as a slow p95.
+func (t *proxyLatencyTracker) recordError(xdsType string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	proxySLOViolations.With(nodeTag.Value(t.node), typeTag.Value(xdsType)).Increment()
+	t.violationRuns++
+	if xdsType != "" {
+		t.lastType = xdsType
+	}
+	wasQuarantined := t.quarantined
+	t.quarantined = t.violationRuns >= sloSustainedViolations
+	if t.quarantined && !wasQuarantined {
+		t.quarantinedAt = time.Now()
+	}
+}
+
+func (t *proxyLatencyTracker) isQuarantined() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.quarantined
+}
+
+type slowProxyInfo struct {
+	ConnectionID  string        `json:"connectionId"`
+	Node          string        `json:"node"`
+	P95Latency    time.Duration `json:"p95Latency"`
+	QuarantinedAt time.Time     `json:"quarantinedAt"`
+	Reason        string        `json:"reason"`
+}
+
+// snapshot returns the current list of quarantined proxies, used by the /debug/slowproxies
+// handler below.
+func (r *slowProxyRegistry) snapshot() []slowProxyInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []slowProxyInfo
+	for _, t := range r.trackers {
+		t.mu.Lock()
+		if t.quarantined {
+			out = append(out, slowProxyInfo{
+				ConnectionID:  t.conID,
+				Node:          t.node,
+				P95Latency:    t.lastViolation,
+				QuarantinedAt: t.quarantinedAt,
+				Reason:        "sustained p95 push latency above SLO threshold or repeated push send failures",
+			})
+		}
+		t.mu.Unlock()
+	}
+	return out
+}
+
+// slowProxiesHandler serves /debug/slowproxies, returning the current quarantine list with
+// each proxy's latency stats and quarantine reason.
+func (s *DiscoveryServer) slowProxiesHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.slowProxyRegistry.snapshot()); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
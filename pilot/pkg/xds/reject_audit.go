@@ -0,0 +1,125 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package xds
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rejectAuditRingSize is the number of most recent rejects kept per proxy. Large enough to
+// cover a burst of rejects from a single bad config push without growing unbounded.
+const rejectAuditRingSize = 20
+
+// rejectAuditEvent is one structured record of a proxy rejecting an xDS response, with
+// enough detail (resource names, the Envoy validation error, and the last-known-good
+// version) to debug a "listener rejected" alert without re-deriving it from raw logs.
+type rejectAuditEvent struct {
+	Time            time.Time `json:"time"`
+	Node            string    `json:"node"`
+	TypeURL         string    `json:"typeUrl"`
+	ErrorCode       string    `json:"errorCode"`
+	ErrorDetail     string    `json:"errorDetail"`
+	ResourceNames   []string  `json:"resourceNames"`
+	RejectedVersion string    `json:"rejectedVersion"`
+	LastGoodVersion string    `json:"lastGoodVersion"`
+}
+
+// rejectAuditRing is a fixed-size ring buffer of the most recent reject events for a single
+// proxy, retrievable via /debug/rejects?proxyID=.
+type rejectAuditRing struct {
+	mu     sync.Mutex
+	events []rejectAuditEvent
+}
+
+func (r *rejectAuditRing) add(e rejectAuditEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+	if len(r.events) > rejectAuditRingSize {
+		r.events = r.events[len(r.events)-rejectAuditRingSize:]
+	}
+}
+
+func (r *rejectAuditRing) list() []rejectAuditEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]rejectAuditEvent(nil), r.events...)
+}
+
+// rejectAuditLog keeps a rejectAuditRing per proxy ID (the node passed to recordXDSReject),
+// not per connection ID, so a proxy's rejects survive it reconnecting.
+type rejectAuditLog struct {
+	mu    sync.RWMutex
+	rings map[string]*rejectAuditRing
+}
+
+func newRejectAuditLog() *rejectAuditLog {
+	return &rejectAuditLog{rings: make(map[string]*rejectAuditRing)}
+}
+
+// globalRejectAuditLog is the process-wide reject audit log, mirroring how xdsClientTracker
+// in monitoring.go is a single package-level registry rather than per-DiscoveryServer state.
+var globalRejectAuditLog = newRejectAuditLog()
+
+// record stores the event in the proxy's ring and emits it as a structured JSON log line, so
+// a "listener rejected" alert can be debugged either by tailing pilot logs or by querying
+// /debug/rejects for the specific proxy.
+func (l *rejectAuditLog) record(e rejectAuditEvent) {
+	l.mu.Lock()
+	ring, ok := l.rings[e.Node]
+	if !ok {
+		ring = &rejectAuditRing{}
+		l.rings[e.Node] = ring
+	}
+	l.mu.Unlock()
+	ring.add(e)
+
+	adsLog.Warnw("xds reject",
+		"node", e.Node,
+		"type", e.TypeURL,
+		"errorCode", e.ErrorCode,
+		"errorDetail", e.ErrorDetail,
+		"resources", e.ResourceNames,
+		"rejectedVersion", e.RejectedVersion,
+		"lastGoodVersion", e.LastGoodVersion,
+	)
+}
+
+func (l *rejectAuditLog) forProxy(proxyID string) []rejectAuditEvent {
+	l.mu.RLock()
+	ring, ok := l.rings[proxyID]
+	l.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return ring.list()
+}
+
+// rejectsHandler serves /debug/rejects?proxyID=, returning the ring buffer of recent reject
+// events for that proxy. Registered alongside the other /debug endpoints in InitDebug.
+func rejectsHandler(w http.ResponseWriter, req *http.Request) {
+	proxyID := req.URL.Query().Get("proxyID")
+	w.Header().Set("Content-Type", "application/json")
+	if proxyID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "proxyID query parameter is required"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(globalRejectAuditLog.forProxy(proxyID)); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}